@@ -0,0 +1,319 @@
+package cache
+
+import "container/list"
+
+// 以下默认值均以 AdmissionOptions.Capacity（预期容纳的 key 数量）为基准推算
+const (
+	defaultAdmissionCapacity = 1024
+	defaultSketchWidthFactor = 8  // sketch 宽度默认是 Capacity 的 8 倍
+	defaultSketchDepth       = 4  // 默认使用 4 个哈希函数
+	defaultResetFactor       = 10 // 每 Capacity*10 次计数自增做一次老化重置
+	defaultDoorkeeperFactor  = 8  // doorkeeper 位图大小相对 sketch 宽度的倍数
+)
+
+// AdmissionOptions 配置 TinyLFU 准入策略，所有字段为 0 时使用按 Capacity
+// 推算出的默认值
+type AdmissionOptions struct {
+	// Capacity 是对预期容纳 key 数量的估计，用于推算其余未显式指定的参数
+	Capacity int
+	// Width 是 Count-Min Sketch 每个哈希函数对应的计数器个数
+	Width int
+	// Depth 是 Count-Min Sketch 使用的哈希函数（行）个数
+	Depth int
+	// ResetInterval 是老化重置前允许的计数自增次数，达到该次数后所有
+	// 计数器减半、doorkeeper 清空，使频率估计能够适应访问模式的变化
+	ResetInterval int
+}
+
+func (o AdmissionOptions) withDefaults() AdmissionOptions {
+	if o.Capacity <= 0 {
+		o.Capacity = defaultAdmissionCapacity
+	}
+	if o.Width <= 0 {
+		o.Width = o.Capacity * defaultSketchWidthFactor
+	}
+	if o.Depth <= 0 {
+		o.Depth = defaultSketchDepth
+	}
+	if o.ResetInterval <= 0 {
+		o.ResetInterval = o.Capacity * defaultResetFactor
+	}
+	return o
+}
+
+// admissionCache 在底层 Cache 前挂一层 TinyLFU 准入过滤：只有估计访问
+// 频率更高的 key 才允许挤占底层缓存已有的记录，从而避免一次性扫描污染
+// 底层的 LRU/FIFO 队列
+type admissionCache struct {
+	inner Cache
+
+	sketch        *countMinSketch
+	door          *bloomFilter
+	resetInterval int
+	incr          int
+
+	// order/index 是准入层自己维护的一份“已进入底层缓存的 key”的访问顺序，
+	// 用来在底层 Cache 接口看不到内部结构的情况下猜测淘汰候选；真正的存储
+	// 和淘汰仍然交给 inner 完成
+	order *list.List
+	index map[string]*list.Element
+}
+
+// NewAdmissionCache 用 TinyLFU 准入策略包装 inner，拒绝估计频率低于被
+// 淘汰候选的新 key，减少一次性扫描对热点数据的污染
+func NewAdmissionCache(inner Cache, opts AdmissionOptions) Cache {
+	opts = opts.withDefaults()
+	return &admissionCache{
+		inner:         inner,
+		sketch:        newCountMinSketch(opts.Width, opts.Depth),
+		door:          newBloomFilter(opts.Width*defaultDoorkeeperFactor, opts.Depth),
+		resetInterval: opts.ResetInterval,
+		order:         list.New(),
+		index:         make(map[string]*list.Element),
+	}
+}
+
+// Set 对已经进入底层缓存的 key 直接透传；对尚未出现过的 key 先经过
+// doorkeeper 的首次过滤，再与淘汰候选比较估计频率，决定是否准入
+func (a *admissionCache) Set(key string, value interface{}) {
+	if e, ok := a.index[key]; ok {
+		a.order.MoveToBack(e)
+		a.bumpFreq(key)
+		a.inner.Set(key, value)
+		return
+	}
+
+	firstSighting := !a.door.Contains(key)
+	a.bumpFreq(key)
+	if firstSighting {
+		a.door.Add(key)
+		return
+	}
+
+	if victim, full := a.victimFor(value); full {
+		if a.sketch.Estimate(key) <= a.sketch.Estimate(victim) {
+			return
+		}
+		a.evictTracked(victim)
+	}
+
+	a.inner.Set(key, value)
+	a.index[key] = a.order.PushBack(key)
+}
+
+// Get 命中时顺带把这次访问计入频率估计，未命中的 key 不会被记录，
+// 因为 Get 本身从不写入底层缓存
+func (a *admissionCache) Get(key string) interface{} {
+	v := a.inner.Get(key)
+	if v == nil {
+		return nil
+	}
+	if e, ok := a.index[key]; ok {
+		a.order.MoveToBack(e)
+	}
+	a.bumpFreq(key)
+	return v
+}
+
+// Del 从底层缓存和准入层自己的顺序记录中一并删除
+func (a *admissionCache) Del(key string) {
+	a.evictTracked(key)
+}
+
+// Len 透传底层缓存的记录数
+func (a *admissionCache) Len() int {
+	return a.inner.Len()
+}
+
+// Stat 透传底层缓存的统计信息；准入层拒绝的 key 从未进入底层，因此
+// 不会计入 NSet/NEvict。准入层为腾出空间而移除的淘汰候选是通过
+// inner.Del 删除的，同样不计入 NEvict
+func (a *admissionCache) Stat() Stat {
+	return a.inner.Stat()
+}
+
+// victimFor 判断写入 value 是否会让底层缓存超出容量，超出时返回按准入层
+// 记录顺序排在最前面（近似“最应该被淘汰”）的 key
+func (a *admissionCache) victimFor(value interface{}) (string, bool) {
+	if a.order.Len() == 0 {
+		return "", false
+	}
+	st := a.inner.Stat()
+	if st.MaxBytes <= 0 || st.UsedBytes+CalcLen(value) <= st.MaxBytes {
+		return "", false
+	}
+	return a.order.Front().Value.(string), true
+}
+
+// evictTracked 把 key 从底层缓存和准入层顺序记录中一并移除
+func (a *admissionCache) evictTracked(key string) {
+	if e, ok := a.index[key]; ok {
+		a.order.Remove(e)
+		delete(a.index, key)
+	}
+	a.inner.Del(key)
+}
+
+// bumpFreq 增加 key 在 sketch 中的估计频率，累计到 resetInterval 次后
+// 对 sketch 和 doorkeeper 做一次老化重置
+func (a *admissionCache) bumpFreq(key string) {
+	a.sketch.Increment(key)
+	a.incr++
+	if a.incr >= a.resetInterval {
+		a.sketch.Reset()
+		a.door.Clear()
+		a.incr = 0
+	}
+}
+
+// countMinSketch 是一个 4-bit 计数器的 Count-Min Sketch，用于在常数空间
+// 内估计 key 的访问频率，估计值只会偏高不会偏低
+type countMinSketch struct {
+	width int
+	depth int
+	rows  [][]byte // 每个 byte 打包两个 4-bit 计数器
+}
+
+func newCountMinSketch(width, depth int) *countMinSketch {
+	if width <= 0 {
+		width = 1
+	}
+	if depth <= 0 {
+		depth = 1
+	}
+
+	rows := make([][]byte, depth)
+	for i := range rows {
+		rows[i] = make([]byte, (width+1)/2)
+	}
+	return &countMinSketch{width: width, depth: depth, rows: rows}
+}
+
+func (s *countMinSketch) indexOf(row int, key string) int {
+	h := fnv64a(key) ^ (uint64(row)+1)*fnvPrime64
+	return int(h % uint64(s.width))
+}
+
+func (s *countMinSketch) counter(row, idx int) byte {
+	b := s.rows[row][idx/2]
+	if idx%2 == 0 {
+		return b & 0x0F
+	}
+	return b >> 4
+}
+
+func (s *countMinSketch) setCounter(row, idx int, v byte) {
+	p := &s.rows[row][idx/2]
+	if idx%2 == 0 {
+		*p = (*p &^ 0x0F) | v
+	} else {
+		*p = (*p &^ 0xF0) | (v << 4)
+	}
+}
+
+// Increment 采用保守更新：只把 key 在各行对应计数器中等于当前最小值的
+// 那些加一（饱和于 15），其余行不变，返回自增后的估计频率
+func (s *countMinSketch) Increment(key string) int {
+	const maxCounter = 15
+
+	min := byte(maxCounter)
+	idxs := make([]int, s.depth)
+	for r := 0; r < s.depth; r++ {
+		idx := s.indexOf(r, key)
+		idxs[r] = idx
+		if v := s.counter(r, idx); v < min {
+			min = v
+		}
+	}
+
+	if min < maxCounter {
+		for r, idx := range idxs {
+			if s.counter(r, idx) == min {
+				s.setCounter(r, idx, min+1)
+			}
+		}
+		min++
+	}
+	return int(min)
+}
+
+// Estimate 返回 key 当前的估计访问频率，即各行对应计数器中的最小值
+func (s *countMinSketch) Estimate(key string) int {
+	min := byte(15)
+	for r := 0; r < s.depth; r++ {
+		if v := s.counter(r, s.indexOf(r, key)); v < min {
+			min = v
+		}
+	}
+	return int(min)
+}
+
+// Reset 把所有计数器减半，用于周期性老化：避免早期的热点 key 凭借
+// 饱和的计数器值无限期地压制新的热点
+func (s *countMinSketch) Reset() {
+	for _, row := range s.rows {
+		for i, b := range row {
+			lo := (b & 0x0F) >> 1
+			hi := ((b >> 4) & 0x0F) >> 1
+			row[i] = lo | (hi << 4)
+		}
+	}
+}
+
+// bloomFilter 是准入策略里的 doorkeeper：一个简单的位图布隆过滤器，
+// 用于过滤只出现过一次的 key，避免它们一出现就占用 sketch 里宝贵的
+// “曾经被当作候选”资格之外还直接抢夺底层缓存的位置
+type bloomFilter struct {
+	bits []uint64
+	m    uint64
+	k    int
+}
+
+func newBloomFilter(bits, k int) *bloomFilter {
+	if bits <= 0 {
+		bits = 1
+	}
+	if k <= 0 {
+		k = 1
+	}
+	return &bloomFilter{bits: make([]uint64, (bits+63)/64), m: uint64(bits), k: k}
+}
+
+func (f *bloomFilter) indexes(key string) []uint64 {
+	h1 := fnv64a(key)
+	h2 := fnv64a(key + "\x00")
+	if h2 == 0 {
+		h2 = 1
+	}
+
+	idxs := make([]uint64, f.k)
+	for i := 0; i < f.k; i++ {
+		idxs[i] = (h1 + uint64(i)*h2) % f.m
+	}
+	return idxs
+}
+
+// Contains 返回 key 是否可能已经出现过，可能存在误判为“出现过”的情况，
+// 但不会漏判
+func (f *bloomFilter) Contains(key string) bool {
+	for _, idx := range f.indexes(key) {
+		if f.bits[idx/64]&(1<<(idx%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Add 记录 key 已经出现过
+func (f *bloomFilter) Add(key string) {
+	for _, idx := range f.indexes(key) {
+		f.bits[idx/64] |= 1 << (idx % 64)
+	}
+}
+
+// Clear 清空所有 bit，与 sketch 的老化重置同步进行
+func (f *bloomFilter) Clear() {
+	for i := range f.bits {
+		f.bits[i] = 0
+	}
+}
@@ -0,0 +1,59 @@
+package cache_test
+
+import (
+	"fmt"
+	"testing"
+
+	"cache"
+	"cache/lru"
+
+	"github.com/matryer/is"
+)
+
+func TestAdmissionCacheProtectsHotKeysFromScan(t *testing.T) {
+	is := is.New(t)
+
+	// 容量恰好只够放下 3 个热点 key，没有准入过滤时任何一次 Set 都会
+	// 立刻把某个热点 key 挤出去
+	inner := lru.New(15, nil)
+	ac := cache.NewAdmissionCache(inner, cache.AdmissionOptions{Capacity: 2048})
+
+	hotKeys := []string{"hot-1", "hot-2", "hot-3"}
+	for _, k := range hotKeys {
+		ac.Set(k, k) // 第一次只会被 doorkeeper 记录
+		ac.Set(k, k) // 第二次才真正进入底层缓存
+		for i := 0; i < 5; i++ {
+			ac.Get(k)
+		}
+	}
+
+	// 扫描大量只出现一次的冷 key，每个都卡在 doorkeeper 这一关，
+	// 没有机会进入底层缓存跟热点 key 竞争容量
+	for i := 0; i < 1000; i++ {
+		ac.Set(fmt.Sprintf("scan-%d", i), i)
+	}
+
+	for _, k := range hotKeys {
+		is.Equal(ac.Get(k), k)
+	}
+}
+
+func TestAdmissionCacheRejectsLowerFrequencyKey(t *testing.T) {
+	is := is.New(t)
+
+	inner := lru.New(1, nil)
+	ac := cache.NewAdmissionCache(inner, cache.AdmissionOptions{Capacity: 4})
+
+	ac.Set("hot", "h")
+	ac.Set("hot", "h") // 越过 doorkeeper，进入底层缓存
+	is.Equal(ac.Get("hot"), "h")
+	for i := 0; i < 5; i++ {
+		ac.Get("hot") // 反复访问提升 "hot" 的估计频率
+	}
+
+	ac.Set("cold", "c") // 第一次只被 doorkeeper 记录
+	is.Equal(ac.Get("cold"), nil)
+	ac.Set("cold", "c") // 第二次需要淘汰 "hot" 才能腾出空间，但频率不够，准入被拒绝
+	is.Equal(ac.Get("cold"), nil)
+	is.Equal(ac.Get("hot"), "h")
+}
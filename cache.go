@@ -0,0 +1,252 @@
+package cache
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"cache/peers"
+	"cache/singleflight"
+)
+
+// Cache 是底层缓存后端的抽象，fifo、lru 等具体实现都需要满足该接口
+type Cache interface {
+	// Set 写入一条 key-value 记录
+	Set(key string, value interface{})
+	// Get 读取 key 对应的值，不存在时返回 nil
+	Get(key string) interface{}
+	// Del 删除 key 对应的记录
+	Del(key string)
+	// Len 返回当前记录数
+	Len() int
+	// Stat 返回该缓存的命中率等统计信息
+	Stat() Stat
+}
+
+// Stat 记录一个缓存的运行时统计信息
+type Stat struct {
+	NGet      int // Get 方法被调用的次数
+	NHit      int // Get 命中缓存的次数
+	NSet      int // Set 方法被调用的次数
+	NEvict    int // 因容量不足被动淘汰的记录数，不包括显式 Del
+	UsedBytes int // 已使用的字节数
+	MaxBytes  int // 容量上限(单位byte)，0表示没有限制
+	Len       int // 当前记录数
+}
+
+// Value 用于计算一个值占用的字节数，具体的值类型可以自行实现该接口
+// 以获得更精确的大小估算
+type Value interface {
+	Len() int
+}
+
+// CalcLen 计算 value 占用的字节数，value 未实现 Value 接口时按常见类型
+// 做粗略估计
+func CalcLen(value interface{}) int {
+	if v, ok := value.(Value); ok {
+		return v.Len()
+	}
+
+	switch v := value.(type) {
+	case string:
+		return len(v)
+	case []byte:
+		return len(v)
+	default:
+		// 无法准确估计大小时，按一个指针的大小粗略计算
+		return 8
+	}
+}
+
+// Getter 用于在缓存未命中时从数据源加载数据
+type Getter interface {
+	Get(key string) interface{}
+}
+
+// GetFunc 是函数适配器，使普通函数也能作为 Getter 使用
+type GetFunc func(key string) interface{}
+
+// Get 实现 Getter 接口
+func (f GetFunc) Get(key string) interface{} {
+	return f(key)
+}
+
+// TourCache 组合了一个数据加载器和一个底层缓存实现：
+// 命中缓存直接返回，未命中时调用 getter 加载数据并回填缓存
+type TourCache struct {
+	name   string
+	mu     sync.Mutex
+	getter Getter
+	cache  Cache
+	loader singleflight.Group
+	peers  peers.PeerPicker
+
+	nGet int
+	nHit int
+}
+
+var (
+	groupsMu sync.RWMutex
+	groups   = make(map[string]*TourCache)
+)
+
+// NewTourCache 创建一个 TourCache，getter 不能为空。name 用于在分布式
+// 场景下标识这个缓存组：配合 RegisterPeers 使用时，其他节点会通过
+// name/key 向本节点请求数据，参见 GetTourCache
+func NewTourCache(name string, getter Getter, cache Cache) *TourCache {
+	if getter == nil {
+		panic("cache: nil getter")
+	}
+	t := &TourCache{
+		name:   name,
+		getter: getter,
+		cache:  cache,
+	}
+
+	groupsMu.Lock()
+	groups[name] = t
+	groupsMu.Unlock()
+
+	return t
+}
+
+// GetTourCache 返回通过 NewTourCache 注册的、名为 name 的 TourCache，
+// 不存在时返回 nil。节点收到其他节点的请求时用它找到对应的本地缓存组
+func GetTourCache(name string) *TourCache {
+	groupsMu.RLock()
+	defer groupsMu.RUnlock()
+	return groups[name]
+}
+
+// RegisterPeers 为 TourCache 注册一个 PeerPicker：注册之后，本地未命中
+// 时会先尝试向 key 所属的远程节点读取，只有 key 确实属于本节点时才会
+// 调用本地 getter。同一个 TourCache 只能注册一次
+func (t *TourCache) RegisterPeers(picker peers.PeerPicker) {
+	if t.peers != nil {
+		panic("cache: RegisterPeers called more than once")
+	}
+	t.peers = picker
+}
+
+// Get 优先从缓存中读取；未命中时，如果注册了 PeerPicker 且 key 属于某个
+// 远程节点，就向该节点读取，否则调用本地 getter 加载数据并写回缓存。
+// 并发的多个 Get 如果同时未命中同一个 key，只有其中一个会真正调用本地
+// getter，其余的共享同一次加载结果，避免对后端存储的重复访问
+func (t *TourCache) Get(key string) interface{} {
+	t.mu.Lock()
+	t.nGet++
+	v := t.cache.Get(key)
+	if v != nil {
+		t.nHit++
+	}
+	t.mu.Unlock()
+	if v != nil {
+		return v
+	}
+
+	if t.peers != nil {
+		if peer, ok := t.peers.PickPeer(key); ok {
+			v, err := t.getFromPeer(peer, key)
+			if err == nil {
+				return v
+			}
+			log.Printf("cache: fetch key %q from peer failed, falling back to local getter: %v", key, err)
+		}
+	}
+
+	return t.getLocally(key)
+}
+
+// getLocally 在本地加载 key 对应的值并写回本地缓存，不会再咨询
+// PeerPicker；Get 在 key 属于本节点、没有注册 PeerPicker 或向远程节点
+// 读取失败时调用它。加载在 singleflight 内进行：先再查一次本地缓存，
+// 仍未命中才调用 getter，并在这次调用结束前写回缓存，因此晚一步未命中
+// 的 Get 要么共享这次加载，要么直接读到已回填的值
+func (t *TourCache) getLocally(key string) interface{} {
+	v, _, _ := t.loader.Do(key, func() (interface{}, error) {
+		t.mu.Lock()
+		cached := t.cache.Get(key)
+		t.mu.Unlock()
+		if cached != nil {
+			return cached, nil
+		}
+
+		v := t.getter.Get(key)
+		if v != nil {
+			t.mu.Lock()
+			t.cache.Set(key, v)
+			t.mu.Unlock()
+		}
+		return v, nil
+	})
+	return v
+}
+
+// getFromPeer 向拥有 key 的远程节点请求数据，成功后把结果也写入本地
+// 缓存，这样下次同一个节点再次请求同一个 key 就无需再走一次网络
+func (t *TourCache) getFromPeer(peer peers.PeerGetter, key string) (interface{}, error) {
+	body, err := peer.Get(t.name, key)
+	if err != nil {
+		return nil, err
+	}
+
+	v := interface{}(string(body))
+	t.mu.Lock()
+	t.cache.Set(key, v)
+	t.mu.Unlock()
+	return v, nil
+}
+
+// encodeValue 把 TourCache 中的值编码成字节序列用于网络传输：
+// string/[]byte 直接转换，其他类型使用 fmt.Sprint 做兜底转换
+func encodeValue(v interface{}) []byte {
+	switch vv := v.(type) {
+	case []byte:
+		return vv
+	case string:
+		return []byte(vv)
+	default:
+		return []byte(fmt.Sprint(vv))
+	}
+}
+
+// Lookup 返回 name 对应的 TourCache 处理 key 的结果，编码为字节序列；
+// 用于配合 cache/httppool.NewHTTPPool 响应其他节点的请求，例如:
+//
+//	pool := httppool.NewHTTPPool(self, cache.Lookup)
+//
+// 这里特意调用 Get 而不是 getLocally：由于集群里所有节点共享同一套一致
+// 性哈希环，收到请求就说明这个节点是 key 的所有者，Get 内部咨询
+// PeerPicker 时会判断出 key 属于自己从而直接走本地缓存/getter，这样已经
+// 加载过的 key 先在 Get 里命中本地缓存，并计入本节点的 get/hit 统计
+func Lookup(name, key string) ([]byte, error) {
+	t := GetTourCache(name)
+	if t == nil {
+		return nil, fmt.Errorf("cache: no such TourCache group %q", name)
+	}
+
+	v := t.Get(key)
+	if v == nil {
+		return nil, fmt.Errorf("cache: key %q not found in group %q", key, name)
+	}
+	return encodeValue(v), nil
+}
+
+// Stat 返回 TourCache 自身的 get/hit 计数，并与底层缓存的统计信息合并，
+// 这样既能看到经过加载器的整体命中情况，也能看到底层缓存的原始状态
+func (t *TourCache) Stat() Stat {
+	t.mu.Lock()
+	s := Stat{
+		NGet: t.nGet,
+		NHit: t.nHit,
+	}
+	t.mu.Unlock()
+
+	bs := t.cache.Stat()
+	s.NSet = bs.NSet
+	s.NEvict = bs.NEvict
+	s.UsedBytes = bs.UsedBytes
+	s.MaxBytes = bs.MaxBytes
+	s.Len = bs.Len
+	return s
+}
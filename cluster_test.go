@@ -0,0 +1,121 @@
+package cache
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"cache/httppool"
+
+	"github.com/matryer/is"
+)
+
+// memCache 是一个仅供本文件测试使用的极简 Cache 实现；之所以不直接用
+// cache/lru，是因为 lru 反过来依赖 cache 包（用到 Cache 接口），而本文件
+// 是 cache 包自身的白盒测试，引入 cache/lru 会构成 import cycle
+type memCache struct {
+	mu sync.Mutex
+	m  map[string]interface{}
+}
+
+func newMemCache() Cache {
+	return &memCache{m: make(map[string]interface{})}
+}
+
+func (c *memCache) Set(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.m[key] = value
+}
+
+func (c *memCache) Get(key string) interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m[key]
+}
+
+func (c *memCache) Del(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.m, key)
+}
+
+func (c *memCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.m)
+}
+
+func (c *memCache) Stat() Stat {
+	return Stat{}
+}
+
+// TestDistributedClusterLoadsEachKeyFromSourceOnce 起 3 个进程内 HTTP 节点，
+// 让它们共享同一份一致性哈希环，然后从任意节点并发请求任意 key，验证
+// 不论请求落在哪个节点，每个 key 都只会被底层数据源加载一次
+func TestDistributedClusterLoadsEachKeyFromSourceOnce(t *testing.T) {
+	is := is.New(t)
+
+	const groupName = "scores"
+	db := map[string]string{"a": "1", "b": "2", "c": "3"}
+
+	var calls int32
+	getter := GetFunc(func(key string) interface{} {
+		atomic.AddInt32(&calls, 1)
+		if v, ok := db[key]; ok {
+			return v
+		}
+		return nil
+	})
+
+	const nodeCount = 3
+	servers := make([]*httptest.Server, nodeCount)
+	addrs := make([]string, nodeCount)
+	for i := 0; i < nodeCount; i++ {
+		servers[i] = httptest.NewUnstartedServer(nil)
+		addrs[i] = "http://" + servers[i].Listener.Addr().String()
+	}
+
+	nodes := make([]*TourCache, nodeCount)
+	for i := 0; i < nodeCount; i++ {
+		node := &TourCache{name: groupName, getter: getter, cache: newMemCache()}
+		nodes[i] = node
+
+		pool := httppool.NewHTTPPool(addrs[i], func(group, key string) ([]byte, error) {
+			if group != groupName {
+				return nil, fmt.Errorf("cluster_test: unknown group %q", group)
+			}
+			v := node.Get(key)
+			if v == nil {
+				return nil, fmt.Errorf("cluster_test: key %q not found", key)
+			}
+			return encodeValue(v), nil
+		})
+		pool.Set(addrs...)
+		node.RegisterPeers(pool)
+
+		servers[i].Config.Handler = pool
+		servers[i].Start()
+	}
+	defer func() {
+		for _, s := range servers {
+			s.Close()
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < nodeCount; i++ {
+		for key, want := range db {
+			wg.Add(1)
+			go func(i int, key, want string) {
+				defer wg.Done()
+				is.Equal(nodes[i].Get(key), want)
+			}(i, key, want)
+		}
+	}
+	wg.Wait()
+
+	is.Equal(int(atomic.LoadInt32(&calls)), len(db))
+}
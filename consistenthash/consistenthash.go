@@ -0,0 +1,65 @@
+// Package consistenthash 实现了一致性哈希算法，用于在分布式缓存中把
+// key 均匀地映射到集群节点上：节点增减时，只有少量 key 需要重新分布，
+// 而不是像普通取模哈希那样几乎全部失效
+package consistenthash
+
+import (
+	"hash/crc32"
+	"sort"
+	"strconv"
+)
+
+// Hash 将字节切片映射为一个 uint32，用于确定 key 在环上的位置
+type Hash func(data []byte) uint32
+
+// Map 维护一致性哈希环：每个真实节点通过若干虚拟节点分散在环上，
+// 相同的 key 总是被路由到同一个真实节点
+type Map struct {
+	hash     Hash
+	replicas int            // 每个真实节点对应的虚拟节点倍数
+	keys     []int          // 排序后的虚拟节点哈希值，即哈希环
+	hashMap  map[int]string // 虚拟节点哈希值 -> 真实节点名称
+}
+
+// New 创建一个一致性哈希环，replicas 是每个真实节点的虚拟节点倍数，
+// fn 为 nil 时使用 crc32.ChecksumIEEE
+func New(replicas int, fn Hash) *Map {
+	m := &Map{
+		replicas: replicas,
+		hash:     fn,
+		hashMap:  make(map[int]string),
+	}
+	if m.hash == nil {
+		m.hash = crc32.ChecksumIEEE
+	}
+	return m
+}
+
+// Add 向环上添加若干真实节点
+func (m *Map) Add(keys ...string) {
+	for _, key := range keys {
+		for i := 0; i < m.replicas; i++ {
+			hash := int(m.hash([]byte(strconv.Itoa(i) + key)))
+			m.keys = append(m.keys, hash)
+			m.hashMap[hash] = key
+		}
+	}
+	sort.Ints(m.keys)
+}
+
+// Get 返回 key 应该落在哪个真实节点上，环为空（没有任何节点）时返回
+// 空字符串
+func (m *Map) Get(key string) string {
+	if len(m.keys) == 0 {
+		return ""
+	}
+
+	hash := int(m.hash([]byte(key)))
+	// 顺时针找到第一个哈希值不小于 key 的虚拟节点；越过环的末尾时回到
+	// 起点，即 idx == len(m.keys) 时取 m.keys[0]
+	idx := sort.Search(len(m.keys), func(i int) bool {
+		return m.keys[i] >= hash
+	})
+
+	return m.hashMap[m.keys[idx%len(m.keys)]]
+}
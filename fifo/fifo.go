@@ -20,6 +20,8 @@ type fifo struct {
 
 	ll *list.List
 	cache map[string]*list.Element
+
+	nGet, nHit, nSet, nEvict int
 }
 
 type entry struct {
@@ -42,6 +44,7 @@ func New(maxBytes int, onEvicted func(key string, value interface{})) cache.Cach
 }
 
 func (f *fifo) Set(key string, value interface{}) {
+	f.nSet++
 	if e, ok := f.cache[key]; ok {
 		f.ll.MoveToBack(e)
 		en := e.Value.(*entry)
@@ -62,7 +65,9 @@ func (f *fifo) Set(key string, value interface{}) {
 
 // Get 方法会从cache中获取key对应的值，nil表示key不存在
 func (f *fifo) Get(key string) interface{} {
+	f.nGet++
 	if e, ok := f.cache[key]; ok {
+		f.nHit++
 		return e.Value.(*entry).value
 	}
 
@@ -78,6 +83,9 @@ func (f *fifo) Del(key string) {
 
 // DelOldest 方法会从cache中删除最旧的纪录
 func (f *fifo) DelOldest() {
+	if f.ll.Front() != nil {
+		f.nEvict++
+	}
 	f.removeElement(f.ll.Front())
 }
 
@@ -100,3 +108,16 @@ func (f *fifo) removeElement(e *list.Element) {
 func (f *fifo) Len() int {
 	return f.ll.Len()
 }
+
+// Stat 返回当前cache的统计信息
+func (f *fifo) Stat() cache.Stat {
+	return cache.Stat{
+		NGet:      f.nGet,
+		NHit:      f.nHit,
+		NSet:      f.nSet,
+		NEvict:    f.nEvict,
+		UsedBytes: f.usedBytes,
+		MaxBytes:  f.maxBytes,
+		Len:       f.Len(),
+	}
+}
@@ -0,0 +1,31 @@
+package fifo_test
+
+import (
+	"testing"
+
+	"cache"
+	"cache/fifo"
+
+	"github.com/matryer/is"
+)
+
+func TestStatTracksGetSetAndEvict(t *testing.T) {
+	is := is.New(t)
+
+	c := fifo.New(cache.CalcLen("v1")+cache.CalcLen("v2"), nil)
+
+	c.Set("k1", "v1")
+	c.Set("k2", "v2")
+	is.Equal(c.Get("k2"), "v2") // FIFO 淘汰只看写入顺序，访问不影响淘汰顺序
+
+	c.Set("k3", "v3") // 容量只够 2 个值，淘汰最先写入的 "k1"
+
+	is.Equal(c.Get("missing"), nil)
+
+	st := c.Stat()
+	is.Equal(st.NSet, 3)
+	is.Equal(st.NGet, 2)
+	is.Equal(st.NHit, 1)
+	is.Equal(st.NEvict, 1)
+	is.Equal(st.Len, 2)
+}
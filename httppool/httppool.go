@@ -0,0 +1,129 @@
+// Package httppool 实现了基于 HTTP 的节点间传输层：既作为 server 响应
+// 其他节点发来的读请求，也作为 client 通过 cache/peers.PeerGetter 向其他
+// 节点发起请求，并借助 cache/consistenthash 实现 cache/peers.PeerPicker，
+// 从而让多个 cache.TourCache 实例组成一个集群
+package httppool
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"cache/consistenthash"
+	"cache/peers"
+)
+
+const (
+	defaultBasePath = "/cache/"
+	defaultReplicas = 50
+)
+
+// Lookup 根据 group 名称和 key 返回对应的数据，由使用方提供，使
+// HTTPPool 不必直接依赖某个具体的缓存实现
+type Lookup func(group, key string) ([]byte, error)
+
+// HTTPPool 是一个节点在集群中的 HTTP 端点，同时承担 server 和 client
+// 两个角色
+type HTTPPool struct {
+	self     string // 本节点的 baseURL，例如 "https://10.0.0.1:8000"
+	basePath string
+	lookup   Lookup
+
+	mu          sync.Mutex
+	peersRing   *consistenthash.Map
+	httpGetters map[string]*httpGetter // 每个远程节点的 baseURL -> 对应的 client
+}
+
+// NewHTTPPool 创建一个 HTTPPool，self 是本节点的地址，lookup 用于在
+// 收到其他节点的请求时从本地读取数据
+func NewHTTPPool(self string, lookup Lookup) *HTTPPool {
+	return &HTTPPool{
+		self:     self,
+		basePath: defaultBasePath,
+		lookup:   lookup,
+	}
+}
+
+// Set 更新集群中所有节点（包括本节点自己）的地址列表，并重建一致性
+// 哈希环，节点列表发生变化时需要重新调用
+func (p *HTTPPool) Set(peerAddrs ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.peersRing = consistenthash.New(defaultReplicas, nil)
+	p.peersRing.Add(peerAddrs...)
+	p.httpGetters = make(map[string]*httpGetter, len(peerAddrs))
+	for _, addr := range peerAddrs {
+		p.httpGetters[addr] = &httpGetter{baseURL: addr + p.basePath}
+	}
+}
+
+// PickPeer 实现 peers.PeerPicker：为 key 选出负责它的节点；如果选出的
+// 正是本节点，返回 ok=false，交由调用方自己处理
+func (p *HTTPPool) PickPeer(key string) (peers.PeerGetter, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.peersRing == nil {
+		return nil, false
+	}
+	if peer := p.peersRing.Get(key); peer != "" && peer != p.self {
+		return p.httpGetters[peer], true
+	}
+	return nil, false
+}
+
+// ServeHTTP 响应形如 "<basePath><group>/<key>" 的请求
+func (p *HTTPPool) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, p.basePath) {
+		http.Error(w, "unexpected path: "+r.URL.Path, http.StatusBadRequest)
+		return
+	}
+	log.Printf("[httppool %s] %s %s", p.self, r.Method, r.URL.Path)
+
+	parts := strings.SplitN(r.URL.Path[len(p.basePath):], "/", 2)
+	if len(parts) != 2 {
+		http.Error(w, "bad request, expected <group>/<key>", http.StatusBadRequest)
+		return
+	}
+	group, key := parts[0], parts[1]
+
+	body, err := p.lookup(group, key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Write(body)
+}
+
+// httpGetter 实现 peers.PeerGetter，向某个远程节点发起 HTTP 请求
+type httpGetter struct {
+	baseURL string
+}
+
+// Get 向远程节点请求 group 下 key 对应的值
+func (h *httpGetter) Get(group, key string) ([]byte, error) {
+	u := fmt.Sprintf("%v%v/%v", h.baseURL, url.QueryEscape(group), url.QueryEscape(key))
+
+	res, err := http.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("httppool: server returned %v", res.Status)
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("httppool: reading response body: %v", err)
+	}
+	return body, nil
+}
@@ -0,0 +1,122 @@
+package lru
+
+import (
+	"cache"
+	"container/list"
+)
+
+// LRU 缓存，不是并发安全
+type lru struct {
+	// 缓存容量最大值(单位byte)
+	maxBytes int
+
+	// 当一个 entry 从缓存中移除时调用该回调函数，默认为 nil
+	onEvicted func(key string, value interface{})
+
+	// 已使用的字节数，只包括值，key不算
+	usedBytes int
+
+	ll    *list.List
+	cache map[string]*list.Element
+
+	nGet, nHit, nSet, nEvict int
+}
+
+type entry struct {
+	key   string
+	value interface{}
+}
+
+func (e *entry) Len() int {
+	return cache.CalcLen(e.value)
+}
+
+// New 创建新的 cache，若maxBytes是0，表示没有容量限制
+func New(maxBytes int, onEvicted func(key string, value interface{})) cache.Cache {
+	return &lru{
+		maxBytes:  maxBytes,
+		onEvicted: onEvicted,
+		ll:        list.New(),
+		cache:     make(map[string]*list.Element),
+	}
+}
+
+func (l *lru) Set(key string, value interface{}) {
+	l.nSet++
+	if e, ok := l.cache[key]; ok {
+		l.ll.MoveToBack(e)
+		en := e.Value.(*entry)
+		l.usedBytes = l.usedBytes - cache.CalcLen(en.value) + cache.CalcLen(value)
+		en.value = value
+		return
+	}
+
+	en := &entry{key, value}
+	e := l.ll.PushBack(en)
+	l.cache[key] = e
+
+	l.usedBytes += en.Len()
+	if l.maxBytes > 0 && l.usedBytes > l.maxBytes {
+		l.DelOldest()
+	}
+}
+
+// Get 方法会从cache中获取key对应的值，并将其标记为最近访问，nil表示key不存在
+func (l *lru) Get(key string) interface{} {
+	l.nGet++
+	if e, ok := l.cache[key]; ok {
+		l.nHit++
+		l.ll.MoveToBack(e)
+		return e.Value.(*entry).value
+	}
+
+	return nil
+}
+
+// Del 方法会从cache中删除key对应的记录
+func (l *lru) Del(key string) {
+	if e, ok := l.cache[key]; ok {
+		l.removeElement(e)
+	}
+}
+
+// DelOldest 方法会从cache中删除最近最少访问的记录
+func (l *lru) DelOldest() {
+	if l.ll.Front() != nil {
+		l.nEvict++
+	}
+	l.removeElement(l.ll.Front())
+}
+
+func (l *lru) removeElement(e *list.Element) {
+	if e == nil {
+		return
+	}
+
+	l.ll.Remove(e)
+	en := e.Value.(*entry)
+	l.usedBytes -= en.Len()
+	delete(l.cache, en.key)
+
+	if l.onEvicted != nil {
+		l.onEvicted(en.key, en.value)
+	}
+}
+
+// Len 返回当前cache中的记录数
+func (l *lru) Len() int {
+	return l.ll.Len()
+}
+
+// Stat 返回当前cache的统计信息
+func (l *lru) Stat() cache.Stat {
+	return cache.Stat{
+		NGet:      l.nGet,
+		NHit:      l.nHit,
+		NSet:      l.nSet,
+		NEvict:    l.nEvict,
+		UsedBytes: l.usedBytes,
+		MaxBytes:  l.maxBytes,
+		Len:       l.Len(),
+	}
+}
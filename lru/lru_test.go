@@ -0,0 +1,30 @@
+package lru_test
+
+import (
+	"testing"
+
+	"cache"
+	"cache/lru"
+
+	"github.com/matryer/is"
+)
+
+func TestStatTracksGetSetAndEvict(t *testing.T) {
+	is := is.New(t)
+
+	c := lru.New(cache.CalcLen("v1")+cache.CalcLen("v2"), nil)
+
+	c.Set("k1", "v1")
+	c.Set("k2", "v2")
+	is.Equal(c.Get("k1"), "v1")
+	is.Equal(c.Get("missing"), nil)
+
+	c.Set("k3", "v3") // 容量只够 2 个值，淘汰最久未访问的 "k2"
+
+	st := c.Stat()
+	is.Equal(st.NSet, 3)
+	is.Equal(st.NGet, 2)
+	is.Equal(st.NHit, 1)
+	is.Equal(st.NEvict, 1)
+	is.Equal(st.Len, 2)
+}
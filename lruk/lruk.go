@@ -0,0 +1,272 @@
+// Package lruk 实现 LRU-K 淘汰策略，相比经典 LRU，一次性的扫描无法污染
+// 主缓存中的热点数据：只有被访问满 K 次的 key 才会从 history 队列晋升到
+// main 队列，推荐使用 LRU-2（即 k=2）作为默认值。
+package lruk
+
+import (
+	"cache"
+	"container/list"
+)
+
+// defaultHistoryCap 是 history 队列容量(记录数)的默认值。history 队列本身
+// 是按记录数而非字节数限额的（尚未晋升的 key 还没有确定的字节占用），
+// 不能从 maxBytes（main 队列的字节预算）换算得到，两者单位不同，否则对
+// 任何现实的字节容量，换算出的记录数上限都会大到实际上永远不会触发淘汰
+const defaultHistoryCap = 1024
+
+// defaultK 是晋升到 main 队列所需访问次数的默认值，即 LRU-2
+const defaultK = 2
+
+// lruK 维护两个队列：
+//   - history：FIFO 队列，记录 key 的访问次数，未晋升的 key 只存在于此处
+//   - main：按最近最少使用顺序维护已晋升的热点 key，真正参与容量淘汰
+type lruK struct {
+	k int
+
+	maxBytes  int
+	usedBytes int
+	main      *list.List
+	mainCache map[string]*list.Element
+
+	historyCap int
+	history    *list.List
+	historyIdx map[string]*list.Element
+
+	onEvicted func(key string, value interface{})
+
+	nGet, nHit, nSet, nEvict int
+}
+
+type mainEntry struct {
+	key   string
+	value interface{}
+}
+
+func (e *mainEntry) Len() int {
+	return cache.CalcLen(e.value)
+}
+
+// historyEntry 记录一个尚未晋升的 key 的访问次数。hasValue 为 false 表示
+// 该 key 只被 Get 访问过（未命中），还没有通过 Set 写入过真正的值
+type historyEntry struct {
+	key      string
+	value    interface{}
+	hasValue bool
+	count    int
+}
+
+// New 创建一个 LRU-K 缓存，maxBytes 是 main 队列的容量上限(单位byte)，
+// 为 0 表示没有容量限制；k 表示晋升到 main 队列所需的访问次数，k<=0 时
+// 使用默认值 2（即 LRU-2）。history 队列容量默认为 defaultHistoryCap 条
+// 记录，如需按预期的 key 数量自定义请使用 NewWithHistoryCap
+func New(maxBytes int, k int, onEvicted func(key string, value interface{})) cache.Cache {
+	return NewWithHistoryCap(maxBytes, k, 0, onEvicted)
+}
+
+// NewWithHistoryCap 与 New 类似，额外允许显式指定 history 队列的容量
+// (记录数)，historyCap<=0 时使用默认值 defaultHistoryCap
+func NewWithHistoryCap(maxBytes, k, historyCap int, onEvicted func(key string, value interface{})) cache.Cache {
+	if k <= 0 {
+		k = defaultK
+	}
+	if historyCap <= 0 {
+		historyCap = defaultHistoryCap
+	}
+
+	return &lruK{
+		k:          k,
+		maxBytes:   maxBytes,
+		main:       list.New(),
+		mainCache:  make(map[string]*list.Element),
+		historyCap: historyCap,
+		history:    list.New(),
+		historyIdx: make(map[string]*list.Element),
+		onEvicted:  onEvicted,
+	}
+}
+
+func (c *lruK) Set(key string, value interface{}) {
+	c.nSet++
+	if e, ok := c.mainCache[key]; ok {
+		c.updateMainValue(e, value)
+		return
+	}
+
+	e, ok := c.historyIdx[key]
+	if !ok {
+		c.touch(key, value, true)
+		return
+	}
+
+	he := e.Value.(*historyEntry)
+	he.count++
+	he.value = value
+	he.hasValue = true
+	if he.count >= c.k {
+		c.promote(e, he)
+	}
+}
+
+// Get 方法会从cache中获取key对应的值，nil表示key不存在。若key正处于
+// history 队列中，本次访问也会计入晋升所需的访问次数
+func (c *lruK) Get(key string) interface{} {
+	c.nGet++
+	if e, ok := c.mainCache[key]; ok {
+		c.nHit++
+		c.main.MoveToBack(e)
+		return e.Value.(*mainEntry).value
+	}
+
+	e, ok := c.historyIdx[key]
+	if !ok {
+		c.touch(key, nil, false)
+		return nil
+	}
+
+	he := e.Value.(*historyEntry)
+	if he.hasValue {
+		c.nHit++
+	}
+	he.count++
+	if he.count >= c.k && he.hasValue {
+		c.promote(e, he)
+	}
+	return he.value
+}
+
+// Del 方法会从cache中删除key对应的记录，不论其位于 main 还是 history 队列
+func (c *lruK) Del(key string) {
+	if e, ok := c.mainCache[key]; ok {
+		c.removeMainElement(e)
+		return
+	}
+	if e, ok := c.historyIdx[key]; ok {
+		c.removeHistoryElement(e)
+	}
+}
+
+// Len 返回当前cache中有值的记录数，包括已晋升到 main 队列和仍留在
+// history 队列中但已经 Set 过的记录
+func (c *lruK) Len() int {
+	n := c.main.Len()
+	for e := c.history.Front(); e != nil; e = e.Next() {
+		if e.Value.(*historyEntry).hasValue {
+			n++
+		}
+	}
+	return n
+}
+
+// touch 为首次出现的 key 在 history 队列中建立一条新记录，若访问次数
+// 一步到位即达到 k（例如 k==1）则直接晋升
+func (c *lruK) touch(key string, value interface{}, hasValue bool) {
+	he := &historyEntry{key: key, count: 1, value: value, hasValue: hasValue}
+	e := c.history.PushBack(he)
+	c.historyIdx[key] = e
+
+	if he.count >= c.k && he.hasValue {
+		c.promote(e, he)
+		return
+	}
+
+	if c.historyCap > 0 && c.history.Len() > c.historyCap {
+		c.evictHistoryOldest()
+	}
+}
+
+// promote 将一条达到晋升条件的 history 记录移入 main 队列
+func (c *lruK) promote(e *list.Element, he *historyEntry) {
+	c.history.Remove(e)
+	delete(c.historyIdx, he.key)
+	c.setMain(he.key, he.value)
+}
+
+func (c *lruK) setMain(key string, value interface{}) {
+	if e, ok := c.mainCache[key]; ok {
+		c.updateMainValue(e, value)
+		return
+	}
+
+	en := &mainEntry{key, value}
+	e := c.main.PushBack(en)
+	c.mainCache[key] = e
+	c.usedBytes += en.Len()
+
+	for c.maxBytes > 0 && c.usedBytes > c.maxBytes {
+		c.evictMainOldest()
+	}
+}
+
+func (c *lruK) updateMainValue(e *list.Element, value interface{}) {
+	c.main.MoveToBack(e)
+	en := e.Value.(*mainEntry)
+	c.usedBytes = c.usedBytes - cache.CalcLen(en.value) + cache.CalcLen(value)
+	en.value = value
+}
+
+func (c *lruK) evictMainOldest() {
+	c.nEvict++
+	c.removeMainElement(c.main.Front())
+}
+
+func (c *lruK) removeMainElement(e *list.Element) {
+	if e == nil {
+		return
+	}
+
+	c.main.Remove(e)
+	en := e.Value.(*mainEntry)
+	c.usedBytes -= en.Len()
+	delete(c.mainCache, en.key)
+
+	if c.onEvicted != nil {
+		c.onEvicted(en.key, en.value)
+	}
+}
+
+// evictHistoryOldest 在 history 队列超出容量时挑选一条记录淘汰。只被
+// Get 访问过、从未 Set 过的占位记录（hasValue==false）不应该与真正有值
+// 的记录抢占容量：优先淘汰最早的占位记录，只有 history 队列中全部都是
+// 有值记录时才退化为淘汰队首的那条
+func (c *lruK) evictHistoryOldest() {
+	victim := c.history.Front()
+	for e := c.history.Front(); e != nil; e = e.Next() {
+		if !e.Value.(*historyEntry).hasValue {
+			victim = e
+			break
+		}
+	}
+
+	if victim.Value.(*historyEntry).hasValue {
+		c.nEvict++
+	}
+	c.removeHistoryElement(victim)
+}
+
+// Stat 返回当前cache的统计信息，usedBytes/maxBytes 只反映已晋升的 main
+// 队列，history 队列中尚未晋升的记录不计入容量
+func (c *lruK) Stat() cache.Stat {
+	return cache.Stat{
+		NGet:      c.nGet,
+		NHit:      c.nHit,
+		NSet:      c.nSet,
+		NEvict:    c.nEvict,
+		UsedBytes: c.usedBytes,
+		MaxBytes:  c.maxBytes,
+		Len:       c.Len(),
+	}
+}
+
+func (c *lruK) removeHistoryElement(e *list.Element) {
+	if e == nil {
+		return
+	}
+
+	he := e.Value.(*historyEntry)
+	c.history.Remove(e)
+	delete(c.historyIdx, he.key)
+
+	if he.hasValue && c.onEvicted != nil {
+		c.onEvicted(he.key, he.value)
+	}
+}
@@ -0,0 +1,107 @@
+package lruk_test
+
+import (
+	"cache/lruk"
+	"fmt"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestPromotionAfterExactlyKAccesses(t *testing.T) {
+	is := is.New(t)
+
+	var evicted []string
+	c := lruk.NewWithHistoryCap(1, 2, 16, func(key string, value interface{}) {
+		evicted = append(evicted, key)
+	})
+
+	c.Set("a", "1") // history: a(count=1)
+	is.Equal(len(evicted), 0)
+
+	is.Equal(c.Get("a"), "1") // history: a(count=2) -> promoted to main
+	is.Equal(len(evicted), 0)
+
+	c.Set("b", "2") // history: b(count=1), not promoted yet, main untouched
+	is.Equal(len(evicted), 0)
+	is.Equal(c.Get("a"), "1")
+
+	is.Equal(c.Get("b"), "2") // history: b(count=2) -> promoted, evicts "a" from main
+	is.Equal(evicted, []string{"a"})
+}
+
+func TestScanOfUniqueKeysNeverDisplacesHotItems(t *testing.T) {
+	is := is.New(t)
+
+	evicted := map[string]bool{}
+	c := lruk.NewWithHistoryCap(1, 2, 16, func(key string, value interface{}) {
+		evicted[key] = true
+	})
+
+	c.Set("hot", "v")
+	is.Equal(c.Get("hot"), "v") // promoted to main
+
+	// scanning unique, never-repeated keys only ever touches the history
+	// queue and can never reach k accesses, so "hot" (already in main)
+	// must survive regardless of how many of them are scanned.
+	for i := 0; i < 1000; i++ {
+		c.Set(fmt.Sprintf("scan-%d", i), "v")
+	}
+
+	is.Equal(evicted["hot"], false)
+	is.Equal(c.Get("hot"), "v")
+}
+
+func TestOnEvictedOnHistoryDrop(t *testing.T) {
+	is := is.New(t)
+
+	var evicted []string
+	c := lruk.NewWithHistoryCap(0, 2, 2, func(key string, value interface{}) {
+		evicted = append(evicted, key)
+	})
+
+	c.Set("a", "1")
+	c.Set("b", "2")
+	c.Set("c", "3") // history cap is 2, "a" should be dropped with its value
+
+	is.Equal(evicted, []string{"a"})
+	is.Equal(c.Get("a"), nil)
+	is.Equal(c.Get("b"), "2")
+}
+
+func TestNewDefaultHistoryCapEvictsByEntryCountNotMaxBytes(t *testing.T) {
+	is := is.New(t)
+
+	var evicted []string
+	// maxBytes 是一个字节预算(几十 MB)，如果默认 history 容量被误当成
+	// 这个字节数的倍数来算，就会大到下面的扫描永远撑不满，从而永远不会
+	// 触发淘汰；这里通过公开的 New（而不是 NewWithHistoryCap）验证默认
+	// 容量其实是一个与 maxBytes 无关、数量级合理的记录数
+	c := lruk.New(64<<20, 2, func(key string, value interface{}) {
+		evicted = append(evicted, key)
+	})
+
+	const scanKeys = 2000 // 大于 defaultHistoryCap(1024)
+	for i := 0; i < scanKeys; i++ {
+		c.Set(fmt.Sprintf("scan-%d", i), i) // 每个 key 只写入一次，够不到晋升所需的 k 次访问
+	}
+
+	is.True(len(evicted) > 0)
+	is.Equal(evicted[0], "scan-0") // history 是 FIFO，最先写入的记录最先被淘汰
+}
+
+func TestDelRemovesFromBothStructures(t *testing.T) {
+	is := is.New(t)
+
+	c := lruk.New(0, 2, nil)
+
+	c.Set("a", "1")
+	c.Del("a")
+	is.Equal(c.Get("a"), nil)
+
+	c.Set("b", "1")
+	is.Equal(c.Get("b"), "1") // promoted to main
+	c.Del("b")
+	is.Equal(c.Get("b"), nil)
+	is.Equal(c.Len(), 0)
+}
@@ -0,0 +1,19 @@
+// Package peers 定义了分布式缓存节点之间协作所需的最小接口：
+// PeerPicker 负责为一个 key 找到它所属的节点，PeerGetter 负责向该节点
+// 发起真正的远程读取。具体的传输层（例如基于 HTTP 的 cache/httppool）
+// 实现这两个接口即可接入 cache.TourCache
+package peers
+
+// PeerGetter 是从某个远程节点读取数据的能力，由具体的传输层实现
+type PeerGetter interface {
+	// Get 从远程节点读取 group 下 key 对应的值
+	Get(group, key string) ([]byte, error)
+}
+
+// PeerPicker 根据 key 选择负责该 key 的远程节点
+type PeerPicker interface {
+	// PickPeer 为 key 选择一个远程节点；ok 为 false 表示该 key 应该由
+	// 本地节点自己处理，可能是没有配置其他节点，也可能 key 恰好落在
+	// 本地节点自己身上
+	PickPeer(key string) (peer PeerGetter, ok bool)
+}
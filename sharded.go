@@ -0,0 +1,106 @@
+package cache
+
+import "sync"
+
+const (
+	fnvOffset64 = 14695981039346656037
+	fnvPrime64  = 1099511628211
+)
+
+// fnv64a 是 FNV-1a 的 64 位实现，用于将 key 均匀地映射到各个分片，
+// 相比 hash/fnv 包省去了一次 hash.Hash 对象分配
+func fnv64a(key string) uint64 {
+	h := uint64(fnvOffset64)
+	for i := 0; i < len(key); i++ {
+		h ^= uint64(key[i])
+		h *= fnvPrime64
+	}
+	return h
+}
+
+type cacheShard struct {
+	mu    sync.RWMutex
+	cache Cache
+}
+
+// shardedCache 把 key 按哈希分散到多个各自独立加锁的 Cache 分片上，
+// 用分片级别的锁替代单一大锁，消除高 QPS 场景下的锁竞争瓶颈
+type shardedCache struct {
+	shards []*cacheShard
+}
+
+// NewShardedCache 创建一个拥有 shards 个分片的并发安全 Cache，每个分片
+// 由 factory 创建，彼此独立加锁。若要控制总容量，应在调用前把期望的
+// maxBytes 按 shards 均分后再传入 factory，例如：
+//
+//	cache.NewShardedCache(16, func() cache.Cache {
+//		return lru.New(maxBytes/16, onEvicted)
+//	})
+func NewShardedCache(shards int, factory func() Cache) Cache {
+	if shards <= 0 {
+		shards = 1
+	}
+
+	ss := make([]*cacheShard, shards)
+	for i := range ss {
+		ss[i] = &cacheShard{cache: factory()}
+	}
+	return &shardedCache{shards: ss}
+}
+
+func (s *shardedCache) shardFor(key string) *cacheShard {
+	return s.shards[fnv64a(key)%uint64(len(s.shards))]
+}
+
+func (s *shardedCache) Set(key string, value interface{}) {
+	sh := s.shardFor(key)
+	sh.mu.Lock()
+	sh.cache.Set(key, value)
+	sh.mu.Unlock()
+}
+
+func (s *shardedCache) Get(key string) interface{} {
+	sh := s.shardFor(key)
+	// fifo/lru/lruk 的 Get 会挪动内部链表节点、累加命中计数等，不是
+	// 只读操作，必须持有排他锁，否则并发 Get 会在这些共享状态上产生竞争
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	return sh.cache.Get(key)
+}
+
+func (s *shardedCache) Del(key string) {
+	sh := s.shardFor(key)
+	sh.mu.Lock()
+	sh.cache.Del(key)
+	sh.mu.Unlock()
+}
+
+// Len 返回所有分片记录数之和
+func (s *shardedCache) Len() int {
+	n := 0
+	for _, sh := range s.shards {
+		sh.mu.RLock()
+		n += sh.cache.Len()
+		sh.mu.RUnlock()
+	}
+	return n
+}
+
+// Stat 返回所有分片统计信息相加后的结果
+func (s *shardedCache) Stat() Stat {
+	var agg Stat
+	for _, sh := range s.shards {
+		sh.mu.RLock()
+		st := sh.cache.Stat()
+		sh.mu.RUnlock()
+
+		agg.NGet += st.NGet
+		agg.NHit += st.NHit
+		agg.NSet += st.NSet
+		agg.NEvict += st.NEvict
+		agg.UsedBytes += st.UsedBytes
+		agg.MaxBytes += st.MaxBytes
+		agg.Len += st.Len
+	}
+	return agg
+}
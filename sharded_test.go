@@ -0,0 +1,112 @@
+package cache_test
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"cache"
+	"cache/lru"
+
+	"github.com/matryer/is"
+)
+
+func TestShardedCacheAggregatesStat(t *testing.T) {
+	is := is.New(t)
+
+	c := cache.NewShardedCache(4, func() cache.Cache {
+		return lru.New(0, nil)
+	})
+
+	for i := 0; i < 100; i++ {
+		c.Set(fmt.Sprintf("key-%d", i), i)
+	}
+	for i := 0; i < 100; i++ {
+		c.Get(fmt.Sprintf("key-%d", i))
+	}
+	c.Get("missing")
+
+	is.Equal(c.Len(), 100)
+
+	st := c.Stat()
+	is.Equal(st.NSet, 100)
+	is.Equal(st.NGet, 101)
+	is.Equal(st.NHit, 100)
+	is.Equal(st.Len, 100)
+}
+
+// mutexCache 包一个普通 Cache 并用单一互斥锁串行化所有访问，
+// 作为基准测试中衡量分片收益的对照组
+type mutexCache struct {
+	mu sync.Mutex
+	c  cache.Cache
+}
+
+func (m *mutexCache) Set(key string, value interface{}) {
+	m.mu.Lock()
+	m.c.Set(key, value)
+	m.mu.Unlock()
+}
+
+func (m *mutexCache) Get(key string) interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.c.Get(key)
+}
+
+func (m *mutexCache) Del(key string) {
+	m.mu.Lock()
+	m.c.Del(key)
+	m.mu.Unlock()
+}
+
+func (m *mutexCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.c.Len()
+}
+
+func (m *mutexCache) Stat() cache.Stat {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.c.Stat()
+}
+
+const benchKeyCount = 10000
+
+// benchmarkMixedLoad 模拟 90% 读、10% 写的混合负载
+func benchmarkMixedLoad(b *testing.B, c cache.Cache) {
+	for i := 0; i < benchKeyCount; i++ {
+		c.Set(fmt.Sprintf("key-%d", i), i)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		r := rand.New(rand.NewSource(0))
+		for pb.Next() {
+			key := fmt.Sprintf("key-%d", r.Intn(benchKeyCount))
+			if r.Intn(10) == 0 {
+				c.Set(key, r.Int())
+			} else {
+				c.Get(key)
+			}
+		}
+	})
+}
+
+func BenchmarkSingleLock(b *testing.B) {
+	benchmarkMixedLoad(b, &mutexCache{c: lru.New(0, nil)})
+}
+
+func BenchmarkSharded16(b *testing.B) {
+	benchmarkMixedLoad(b, cache.NewShardedCache(16, func() cache.Cache {
+		return lru.New(0, nil)
+	}))
+}
+
+func BenchmarkSharded64(b *testing.B) {
+	benchmarkMixedLoad(b, cache.NewShardedCache(64, func() cache.Cache {
+		return lru.New(0, nil)
+	}))
+}
@@ -0,0 +1,48 @@
+// Package singleflight 提供了一种抑制重复函数调用的机制：同一时刻
+// 针对同一个 key 的多次调用，只会真正执行一次 fn，其余调用方共享同一个
+// 结果，用于避免缓存击穿时对后端存储的并发重复访问
+package singleflight
+
+import "sync"
+
+// call 代表一次正在进行中或已经结束的 fn 调用
+type call struct {
+	wg  sync.WaitGroup
+	val interface{}
+	err error
+}
+
+// Group 管理一组以 key 区分的调用，是 singleflight 的入口
+type Group struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// Do 针对同一个 key，保证同一时刻只有一个 fn 在执行：
+// 如果已有调用正在进行，本次调用会阻塞等待并复用其结果；否则会真正执行
+// fn。返回值的最后一个 bool 表示本次结果是否是从其他调用方那里共享得到的
+func (g *Group) Do(key string, fn func() (interface{}, error)) (v interface{}, err error, shared bool) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call)
+	}
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.val, c.err, true
+	}
+
+	c := new(call)
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	c.val, c.err = fn()
+	c.wg.Done()
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+
+	return c.val, c.err, false
+}
@@ -0,0 +1,85 @@
+package singleflight_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cache/singleflight"
+
+	"github.com/matryer/is"
+)
+
+func TestDoDedupesConcurrentCallsForSameKey(t *testing.T) {
+	is := is.New(t)
+
+	var g singleflight.Group
+	var calls int32
+
+	// fn 阻塞在 release 上，直到所有 goroutine 都有机会加入同一次调用
+	release := make(chan string)
+	fn := func() (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		return <-release, nil
+	}
+
+	const n = 10
+	var wg sync.WaitGroup
+	results := make([]interface{}, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			v, err, _ := g.Do("k", fn)
+			is.NoErr(err)
+			results[i] = v
+		}(i)
+	}
+
+	time.Sleep(100 * time.Millisecond) // 留出时间让上面的 goroutine 都阻塞在同一次调用里
+	release <- "v"
+	wg.Wait()
+
+	is.Equal(atomic.LoadInt32(&calls), int32(1))
+	for _, v := range results {
+		is.Equal(v, "v")
+	}
+}
+
+func TestDoRunsDistinctKeysInParallel(t *testing.T) {
+	is := is.New(t)
+
+	var g singleflight.Group
+
+	release := make(chan struct{})
+	var entered sync.WaitGroup
+	entered.Add(2)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		v, _, _ := g.Do("a", func() (interface{}, error) {
+			entered.Done()
+			<-release
+			return "a-val", nil
+		})
+		is.Equal(v, "a-val")
+	}()
+	go func() {
+		defer wg.Done()
+		v, _, _ := g.Do("b", func() (interface{}, error) {
+			entered.Done()
+			<-release
+			return "b-val", nil
+		})
+		is.Equal(v, "b-val")
+	}()
+
+	// 两个不同 key 的调用都必须先进入各自的 fn 才会关闭 release，
+	// 如果两者互相阻塞（没有真正并行）测试会在这里超时
+	entered.Wait()
+	close(release)
+	wg.Wait()
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/matryer/is"
 	"log"
 	"sync"
+	"sync/atomic"
 	"testing"
 )
 
@@ -25,7 +26,7 @@ func TestTourCache(t *testing.T) {
 		}
 		return nil
 	})
-	tourCache := cache.NewTourCache(getter, lru.New(0, nil))
+	tourCache := cache.NewTourCache("scores", getter, lru.New(0, nil))
 
 	is := is.New(t)
 
@@ -44,6 +45,36 @@ func TestTourCache(t *testing.T) {
 	is.Equal(tourCache.Get("unknown"), nil)
 	is.Equal(tourCache.Get("unknown"), nil)
 
-	is.Equal(tourCache.Stat().NGet, 10)
-	is.Equal(tourCache.Stat().NHit, 4)
+	is.Equal(tourCache.Stat().NGet, 12)
+	is.Equal(tourCache.Stat().NHit, 5)
+}
+
+// TestTourCacheDedupesConcurrentMisses 验证多个 goroutine 并发 Get 同一个
+// 缺失 key 时，getter 只会被调用一次，所有 goroutine 都拿到相同的结果
+func TestTourCacheDedupesConcurrentMisses(t *testing.T) {
+	is := is.New(t)
+
+	var calls int32
+	getter := cache.GetFunc(func(key string) interface{} {
+		atomic.AddInt32(&calls, 1)
+		return "v1"
+	})
+	tourCache := cache.NewTourCache("scores-dedup", getter, lru.New(0, nil))
+
+	const n = 50
+	var wg sync.WaitGroup
+	results := make([]interface{}, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = tourCache.Get("k1")
+		}(i)
+	}
+	wg.Wait()
+
+	is.Equal(atomic.LoadInt32(&calls), int32(1))
+	for _, v := range results {
+		is.Equal(v, "v1")
+	}
 }